@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/codegangsta/cli"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/monitor/server"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/rpc/codec"
+	"github.com/ethereum/go-ethereum/rpc/comms"
+)
+
+var (
+	metricsCommandListenFlag = cli.StringFlag{
+		Name:  "listen",
+		Value: ":6060",
+		Usage: "HTTP listening address to serve /metrics and /metrics.json on",
+	}
+	metricsCommand = cli.Command{
+		Action: metricsServer,
+		Name:   "metrics-server",
+		Usage:  `Geth Metrics Server: expose node metrics over HTTP/JSON and Prometheus`,
+		Description: `
+The metrics-server command attaches to a running node the same way the
+monitor command does, but instead of rendering a terminal UI it serves the
+collected metrics over HTTP so external dashboards (e.g. Prometheus) can
+scrape the node directly.
+`,
+		Flags: []cli.Flag{
+			monitorCommandAttachFlag,
+			metricsCommandListenFlag,
+		},
+	}
+)
+
+// metricsServer attaches to a geth node and serves its metrics over HTTP until
+// interrupted.
+func metricsServer(ctx *cli.Context) {
+	var (
+		client comms.EthereumClient
+		err    error
+	)
+	// Attach to an Ethereum node over IPC or RPC
+	endpoint := ctx.String(monitorCommandAttachFlag.Name)
+	if client, err = comms.ClientFromEndpoint(endpoint, codec.JSON); err != nil {
+		utils.Fatalf("Unable to attach to geth node: %v", err)
+	}
+	defer client.Close()
+
+	xeth := rpc.NewXeth(client)
+	mon := server.New(func() (map[string]interface{}, error) {
+		return retrieveMetrics(xeth)
+	})
+
+	listen := ctx.String(metricsCommandListenFlag.Name)
+	fmt.Println("Serving node metrics on", listen)
+	if err := http.ListenAndServe(listen, mon); err != nil {
+		utils.Fatalf("Failed to serve metrics: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"math"
 	"reflect"
 	"sort"
@@ -27,6 +28,20 @@ var (
 		Value: 5,
 		Usage: "Rows (maximum) to display the charts in",
 	}
+	monitorCommandRefreshFlag = cli.IntFlag{
+		Name:  "refresh",
+		Value: 1,
+		Usage: "Refresh interval in seconds",
+	}
+	monitorCommandHistoryFlag = cli.IntFlag{
+		Name:  "history",
+		Value: 512,
+		Usage: "Number of samples to retain per chart",
+	}
+	monitorCommandListFlag = cli.BoolFlag{
+		Name:  "list",
+		Usage: "List all available metrics and exit",
+	}
 	monitorCommand = cli.Command{
 		Action: monitor,
 		Name:   "monitor",
@@ -39,6 +54,9 @@ to display multiple metrics simultaneously.
 		Flags: []cli.Flag{
 			monitorCommandAttachFlag,
 			monitorCommandRowsFlag,
+			monitorCommandRefreshFlag,
+			monitorCommandHistoryFlag,
+			monitorCommandListFlag,
 		},
 	}
 )
@@ -63,8 +81,24 @@ func monitor(ctx *cli.Context) {
 	if err != nil {
 		utils.Fatalf("Failed to retrieve system metrics: %v", err)
 	}
+	// With no patterns (or an explicit --list), print all available metrics and exit
+	if len(ctx.Args()) == 0 || ctx.Bool(monitorCommandListFlag.Name) {
+		list := expandMetrics(metrics, "")
+		sort.Strings(list)
+		for _, metric := range list {
+			fmt.Println(metric)
+		}
+		return
+	}
 	monitored := resolveMetrics(metrics, ctx.Args())
-	sort.Strings(monitored)
+
+	// Resolve the monitoring refresh interval and sample history to retain
+	seconds := ctx.Int(monitorCommandRefreshFlag.Name)
+	if seconds <= 0 {
+		utils.Fatalf("Refresh interval must be positive: %d", seconds)
+	}
+	interval := time.Duration(seconds) * time.Second
+	history := ctx.Int(monitorCommandHistoryFlag.Name)
 
 	// Create the access function and check that the metric exists
 	value := func(metrics map[string]interface{}, metric string) float64 {
@@ -94,36 +128,34 @@ func monitor(ctx *cli.Context) {
 		rows = max
 	}
 	cols := (len(monitored) + rows - 1) / rows
+	if width := termui.TermWidth() / cols; history < width {
+		utils.Fatalf("Not enough history to cover a single chart width: have %d, want at least %d", history, width)
+	}
 	for i := 0; i < rows; i++ {
 		termui.Body.AddRows(termui.NewRow())
 	}
-	// Create each individual data chart
-	charts := make([]*termui.LineChart, len(monitored))
+	// Create each individual data chart, using the widget type requested for it
+	charts := make([]chartWidget, len(monitored))
 	data := make([][]float64, len(monitored))
 	for i := 0; i < len(data); i++ {
-		data[i] = make([]float64, 512)
+		data[i] = make([]float64, history)
 	}
-	for i, metric := range monitored {
-		charts[i] = termui.NewLineChart()
+	// previous/sampled track the last raw reading of rate metrics, so a delta
+	// can be computed on the following tick instead of plotting raw counters
+	previous := make([]float64, len(monitored))
+	sampled := make([]bool, len(monitored))
 
-		charts[i].Data = make([]float64, 512)
-		charts[i].DataLabels = []string{""}
-		charts[i].Height = termui.TermHeight() / rows
-		charts[i].AxesColor = termui.ColorWhite
-		charts[i].LineColor = termui.ColorGreen
-		charts[i].PaddingBottom = -1
-
-		charts[i].Border.Label = metric
-		charts[i].Border.LabelFgColor = charts[i].Border.FgColor
-		charts[i].Border.FgColor = charts[i].Border.BgColor
+	for i, entry := range monitored {
+		charts[i] = newChartWidget(entry.kind, termui.TermHeight()/rows)
+		charts[i].Update(entry.metric, data[i], entry.rate)
 
 		row := termui.Body.Rows[i%rows]
-		row.Cols = append(row.Cols, termui.NewCol(12/cols, 0, charts[i]))
+		row.Cols = append(row.Cols, termui.NewCol(12/cols, 0, charts[i].Widget()))
 	}
 	termui.Body.Align()
 	termui.Render(termui.Body)
 
-	refresh := time.Tick(time.Second)
+	refresh := time.Tick(interval)
 	for {
 		select {
 		case event := <-termui.EventCh():
@@ -133,7 +165,7 @@ func monitor(ctx *cli.Context) {
 			if event.Type == termui.EventResize {
 				termui.Body.Width = termui.TermWidth()
 				for _, chart := range charts {
-					chart.Height = termui.TermHeight() / rows
+					chart.SetHeight(termui.TermHeight() / rows)
 				}
 				termui.Body.Align()
 				termui.Render(termui.Body)
@@ -143,9 +175,27 @@ func monitor(ctx *cli.Context) {
 			if err != nil {
 				utils.Fatalf("Failed to retrieve system metrics: %v", err)
 			}
-			for i, metric := range monitored {
-				data[i] = append([]float64{value(metrics, metric)}, data[i][:len(data[i])-1]...)
-				updateChart(metric, data[i], charts[i])
+			for i, entry := range monitored {
+				raw := value(metrics, entry.metric)
+
+				sample := raw
+				if entry.rate {
+					if !sampled[i] {
+						// No previous reading yet, nothing to derive a rate from
+						sampled[i] = true
+						previous[i] = raw
+						continue
+					}
+					delta := raw - previous[i]
+					previous[i] = raw
+					if delta < 0 {
+						// Counter was reset (e.g. node restart), treat as no change
+						delta = 0
+					}
+					sample = delta / interval.Seconds()
+				}
+				data[i] = append([]float64{sample}, data[i][:len(data[i])-1]...)
+				charts[i].Update(entry.metric, data[i], entry.rate)
 			}
 			termui.Render(termui.Body)
 		}
@@ -159,15 +209,52 @@ func retrieveMetrics(xeth *rpc.Xeth) (map[string]interface{}, error) {
 }
 
 // resolveMetrics takes a list of input metric patterns, and resolves each to one
-// or more canonical metric names.
-func resolveMetrics(metrics map[string]interface{}, patterns []string) []string {
-	res := []string{}
+// or more canonical metric names, along with the chart type and rate-of-change
+// mode requested for it via optional ":type"/":rate" pattern suffixes (e.g.
+// "p2p/peers:gauge" or "chain/inserts:rate").
+func resolveMetrics(metrics map[string]interface{}, patterns []string) byMetric {
+	var entries byMetric
 	for _, pattern := range patterns {
-		res = append(res, resolveMetric(metrics, pattern, "")...)
+		qualifier, kind, rate := splitChartType(pattern)
+		for _, metric := range resolveMetric(metrics, qualifier, "") {
+			entries = append(entries, monitorEntry{metric, kind, rate})
+		}
 	}
-	return res
+	sort.Sort(entries)
+	return entries
 }
 
+// splitChartType splits a metric pattern into its qualifier, the chart type
+// requested for it (line, bar, sparkline or gauge, defaulting to line) and
+// whether it should be plotted as a rate-of-change instead of its raw value.
+func splitChartType(pattern string) (qualifier string, kind string, rate bool) {
+	parts := strings.Split(pattern, ":")
+	qualifier, kind = parts[0], "line"
+	for _, modifier := range parts[1:] {
+		if modifier == "rate" {
+			rate = true
+		} else {
+			kind = modifier
+		}
+	}
+	return qualifier, kind, rate
+}
+
+// monitorEntry associates a resolved metric with the chart type and value
+// mode (raw or rate-of-change) it should be rendered with.
+type monitorEntry struct {
+	metric string
+	kind   string
+	rate   bool
+}
+
+// byMetric sorts a list of monitorEntry by metric name.
+type byMetric []monitorEntry
+
+func (s byMetric) Len() int           { return len(s) }
+func (s byMetric) Less(i, j int) bool { return s[i].metric < s[j].metric }
+func (s byMetric) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 // resolveMetrics takes a single of input metric pattern, and resolves it to one
 // or more canonical metric names.
 func resolveMetric(metrics map[string]interface{}, pattern string, path string) []string {
@@ -226,9 +313,44 @@ func expandMetrics(metrics map[string]interface{}, path string) []string {
 	return list
 }
 
-// updateChart inserts a dataset into a line chart, scaling appropriately as to
-// not display weird labels, also updating the chart label accordingly.
-func updateChart(metric string, data []float64, chart *termui.LineChart) {
+// chartWidget is a single on-screen termui element capable of displaying a
+// metric's data series, regardless of the concrete widget backing it.
+type chartWidget interface {
+	// Widget returns the underlying termui grid element to lay out and render.
+	Widget() termui.GridBufferer
+
+	// Update scales the given data series into display units and pushes it
+	// into the widget, also refreshing its border label and color. When rate
+	// is set, data is assumed to already be a rate-of-change and is labeled
+	// with a "/s" suffix instead of treated as a raw metric value.
+	Update(metric string, data []float64, rate bool)
+
+	// SetHeight resizes the widget, e.g. in response to a terminal resize.
+	SetHeight(height int)
+}
+
+// newChartWidget constructs the termui widget matching the requested chart
+// kind (line, bar, sparkline or gauge), defaulting to a line chart for any
+// kind it doesn't recognize.
+func newChartWidget(kind string, height int) chartWidget {
+	switch kind {
+	case "bar":
+		return newBarChartWidget(height)
+	case "sparkline":
+		return newSparklineWidget(height)
+	case "gauge":
+		return newGaugeWidget(height)
+	default:
+		return newLineChartWidget(height)
+	}
+}
+
+// scaleSeries finds an appropriate SI (or, for latency percentiles, time) unit
+// for a data series, scales every sample into that unit and returns the unit
+// suffix and color to annotate the chart with. If rate is set, the series is
+// assumed to already hold a per-second rate of change and the label is
+// annotated with a "/s" suffix accordingly.
+func scaleSeries(metric string, data []float64, rate bool) ([]float64, string, termui.Attribute) {
 	dataUnits := []string{"", "K", "M", "G", "T", "E"}
 	timeUnits := []string{"ns", "µs", "ms", "s", "ks", "ms"}
 	colors := []termui.Attribute{termui.ColorBlue, termui.ColorCyan, termui.ColorGreen, termui.ColorYellow, termui.ColorRed, termui.ColorRed}
@@ -242,19 +364,138 @@ func updateChart(metric string, data []float64, chart *termui.LineChart) {
 	for high >= 1000 {
 		high, unit, scale = high/1000, unit+1, scale*1000
 	}
-	// Update the chart's data points with the scaled values
+	scaled := make([]float64, len(data))
 	for i, value := range data {
-		chart.Data[i] = value / scale
+		scaled[i] = value / scale
 	}
-	// Update the chart's label with the scale units
-	chart.Border.Label = metric
-
 	units := dataUnits
 	if strings.Contains(metric, "Percentiles") {
 		units = timeUnits
 	}
-	if len(units[unit]) > 0 {
-		chart.Border.Label += " [" + units[unit] + "]"
+	suffix := units[unit]
+	if rate {
+		suffix += "/s"
+	}
+	label := ""
+	if len(suffix) > 0 {
+		label = " [" + suffix + "]"
+	}
+	return scaled, label, colors[unit]
+}
+
+// lineChartWidget renders a metric as a scrolling line chart, the default and
+// best fit for continuously varying values.
+type lineChartWidget struct{ *termui.LineChart }
+
+func newLineChartWidget(height int) chartWidget {
+	chart := termui.NewLineChart()
+	chart.DataLabels = []string{""}
+	chart.Height = height
+	chart.AxesColor = termui.ColorWhite
+	chart.LineColor = termui.ColorGreen
+	chart.PaddingBottom = -1
+	chart.Border.LabelFgColor = chart.Border.FgColor
+	chart.Border.FgColor = chart.Border.BgColor
+	return &lineChartWidget{chart}
+}
+
+func (w *lineChartWidget) Widget() termui.GridBufferer { return w.LineChart }
+func (w *lineChartWidget) SetHeight(height int)        { w.Height = height }
+
+func (w *lineChartWidget) Update(metric string, data []float64, rate bool) {
+	scaled, unit, color := scaleSeries(metric, data, rate)
+	w.Data = scaled
+	w.Border.Label = metric + unit
+	w.LineColor = color
+}
+
+// barChartWidget renders a metric as a bar chart, better suited than a line
+// chart for small, discrete counter snapshots.
+type barChartWidget struct{ *termui.BarChart }
+
+func newBarChartWidget(height int) chartWidget {
+	chart := termui.NewBarChart()
+	chart.Height = height
+	chart.Border.LabelFgColor = chart.Border.FgColor
+	chart.Border.FgColor = chart.Border.BgColor
+	return &barChartWidget{chart}
+}
+
+func (w *barChartWidget) Widget() termui.GridBufferer { return w.BarChart }
+func (w *barChartWidget) SetHeight(height int)         { w.Height = height }
+
+func (w *barChartWidget) Update(metric string, data []float64, rate bool) {
+	scaled, unit, color := scaleSeries(metric, data, rate)
+
+	bars := make([]int, len(scaled))
+	for i, value := range scaled {
+		bars[i] = int(value)
+	}
+	w.Data = bars
+	w.BarColor = color
+	w.Border.Label = metric + unit
+}
+
+// sparklineWidget renders a metric as a compact sparkline, useful for packing
+// many counters into a small amount of screen space.
+type sparklineWidget struct {
+	*termui.Sparklines
+	line *termui.Sparkline
+}
+
+func newSparklineWidget(height int) chartWidget {
+	line := termui.NewSparkline()
+	line.Height = height - 2
+
+	widget := termui.NewSparklines(line)
+	widget.Height = height
+	widget.Border.LabelFgColor = widget.Border.FgColor
+	widget.Border.FgColor = widget.Border.BgColor
+	return &sparklineWidget{widget, &widget.Lines[0]}
+}
+
+func (w *sparklineWidget) Widget() termui.GridBufferer { return w.Sparklines }
+func (w *sparklineWidget) SetHeight(height int)        { w.Height = height }
+
+func (w *sparklineWidget) Update(metric string, data []float64, rate bool) {
+	scaled, unit, color := scaleSeries(metric, data, rate)
+
+	points := make([]int, len(scaled))
+	for i, value := range scaled {
+		points[i] = int(value)
+	}
+	w.line.Data = points
+	w.line.LineColor = color
+	w.Border.Label = metric + unit
+}
+
+// gaugeWidget renders a metric as a gauge, showing only the most recent
+// sample rather than a history, ideal for instantaneous values such as peer
+// counts rather than ever-growing counters.
+type gaugeWidget struct{ *termui.Gauge }
+
+func newGaugeWidget(height int) chartWidget {
+	gauge := termui.NewGauge()
+	gauge.Height = height
+	gauge.Border.LabelFgColor = gauge.Border.FgColor
+	gauge.Border.FgColor = gauge.Border.BgColor
+	return &gaugeWidget{gauge}
+}
+
+func (w *gaugeWidget) Widget() termui.GridBufferer { return w.Gauge }
+func (w *gaugeWidget) SetHeight(height int)         { w.Height = height }
+
+func (w *gaugeWidget) Update(metric string, data []float64, rate bool) {
+	scaled, unit, color := scaleSeries(metric, data, rate)
+
+	percent := int(scaled[0])
+	switch {
+	case percent < 0:
+		percent = 0
+	case percent > 100:
+		percent = 100
 	}
-	chart.LineColor = colors[unit]
+	w.Percent = percent
+	w.BarColor = color
+	w.Border.Label = metric + unit
 }
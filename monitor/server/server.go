@@ -0,0 +1,152 @@
+// Package server exposes a node's internal metrics over HTTP, so external
+// dashboards can scrape a running node instead of only viewing it through the
+// terminal monitor.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MetricsFunc retrieves the current snapshot of system metrics, in the same
+// tree-shaped format returned by the debug_metrics RPC call.
+type MetricsFunc func() (map[string]interface{}, error)
+
+// MonitorServer serves a node's metrics as flattened JSON on /metrics.json and
+// in Prometheus text exposition format on /metrics.
+type MonitorServer struct {
+	metrics MetricsFunc
+}
+
+// New creates a MonitorServer that retrieves metrics on demand via fetch.
+func New(fetch MetricsFunc) *MonitorServer {
+	return &MonitorServer{metrics: fetch}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *MonitorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics, err := s.metrics()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to retrieve metrics: %v", err), http.StatusInternalServerError)
+		return
+	}
+	switch r.URL.Path {
+	case "/metrics.json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(flatten(metrics, ""))
+
+	case "/metrics":
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writePrometheus(w, metrics, "")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// flatten expands the entire tree of metrics into a flat path->value map,
+// mirroring the monitor command's own expandMetrics helper.
+func flatten(metrics map[string]interface{}, path string) map[string]float64 {
+	flat := make(map[string]float64)
+	for name, metric := range metrics {
+		switch metric := metric.(type) {
+		case float64:
+			flat[path+name] = metric
+		case map[string]interface{}:
+			for k, v := range flatten(metric, path+name+"/") {
+				flat[k] = v
+			}
+		}
+	}
+	return flat
+}
+
+// writePrometheus renders a tree of metrics in Prometheus text exposition
+// format. A submap named "Percentiles" is treated specially: instead of being
+// flattened into the metric name, it is exposed as a single summary with a
+// "quantile" label per percentile, matching how percentile submaps are
+// produced by go-metrics histograms/timers.
+func writePrometheus(w io.Writer, metrics map[string]interface{}, path string) {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch metric := metrics[name].(type) {
+		case float64:
+			full := promName(path + name)
+			fmt.Fprintf(w, "# TYPE %s %s\n", full, promType(full))
+			fmt.Fprintf(w, "%s %s\n", full, promValue(metric))
+
+		case map[string]interface{}:
+			if name == "Percentiles" {
+				writeSummary(w, promName(path), metric)
+				continue
+			}
+			writePrometheus(w, metric, path+name+"/")
+		}
+	}
+}
+
+// writeSummary renders a Percentiles submap as a single Prometheus summary,
+// one {quantile="..."} sample per percentile.
+func writeSummary(w io.Writer, name string, percentiles map[string]interface{}) {
+	fmt.Fprintf(w, "# TYPE %s summary\n", name)
+
+	labels := make([]string, 0, len(percentiles))
+	for label := range percentiles {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		value, ok := percentiles[label].(float64)
+		if !ok {
+			continue
+		}
+		quantile := label
+		if pct, err := strconv.ParseFloat(label, 64); err == nil {
+			quantile = strconv.FormatFloat(pct/100, 'g', -1, 64)
+		}
+		fmt.Fprintf(w, "%s{quantile=\"%s\"} %s\n", name, escapeLabel(quantile), promValue(value))
+	}
+}
+
+// promName mangles a metric path into a Prometheus-compatible metric name by
+// replacing path separators with underscores.
+func promName(path string) string {
+	return strings.Replace(strings.Trim(path, "/"), "/", "_", -1)
+}
+
+// promType returns the Prometheus TYPE hint for a metric name, using a naming
+// heuristic since debug_metrics carries no explicit gauge/counter
+// registration: ever-accumulating counters are conventionally suffixed Count
+// or Total, everything else is treated as an instantaneous gauge.
+func promType(name string) string {
+	if strings.HasSuffix(name, "Count") || strings.HasSuffix(name, "Total") {
+		return "counter"
+	}
+	return "gauge"
+}
+
+// promValue formats a metric sample the way the Prometheus text format
+// expects numeric values to look.
+func promValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes a Prometheus label value per the text exposition format
+// (backslashes, double quotes and newlines must be escaped).
+func escapeLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	return value
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	metrics := map[string]interface{}{
+		"chain": map[string]interface{}{
+			"inserts": 42.0,
+		},
+		"p2p": map[string]interface{}{
+			"peers": 5.0,
+		},
+	}
+	flat := flatten(metrics, "")
+	want := map[string]float64{
+		"chain/inserts": 42.0,
+		"p2p/peers":     5.0,
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("flatten mismatch: got %v, want %v", flat, want)
+	}
+}
+
+func TestWritePrometheusGaugeAndCounter(t *testing.T) {
+	metrics := map[string]interface{}{
+		"p2p": map[string]interface{}{
+			"peers": 5.0,
+		},
+		"chain": map[string]interface{}{
+			"insertsCount": 42.0,
+		},
+	}
+	buf := new(bytes.Buffer)
+	writePrometheus(buf, metrics, "")
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE chain_insertsCount counter",
+		"chain_insertsCount 42",
+		"# TYPE p2p_peers gauge",
+		"p2p_peers 5",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWritePrometheusPercentiles(t *testing.T) {
+	metrics := map[string]interface{}{
+		"rpc": map[string]interface{}{
+			"Percentiles": map[string]interface{}{
+				"50": 1.5,
+				"95": 4.2,
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	writePrometheus(buf, metrics, "")
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE rpc summary",
+		`rpc{quantile="0.5"} 1.5`,
+		`rpc{quantile="0.95"} 4.2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteSummaryEscapesLabels(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writeSummary(buf, "latency", map[string]interface{}{
+		`weird"label\`: 3.0,
+	})
+	out := buf.String()
+
+	want := `latency{quantile="weird\"label\\"} 3`
+	if !strings.Contains(out, want) {
+		t.Errorf("expected escaped label output %q, got:\n%s", want, out)
+	}
+}
@@ -0,0 +1,204 @@
+package state
+
+import (
+	"io"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// account tracks the nonces reserved for a single address on top of its
+// on-chain nonce. nstart is the nonce value nonces[0] corresponds to; nonces
+// is a growing bitmap marking which of the nonces starting at nstart have
+// already been handed out via NewNonce.
+type account struct {
+	stateObject *StateObject
+	nstart      uint64
+	nonces      []bool
+}
+
+// newAccount creates a fresh, empty managed account rooted at so's current
+// on-chain nonce.
+func newAccount(so *StateObject) *account {
+	return &account{stateObject: so, nstart: so.nonce}
+}
+
+// ManagedState wraps a StateDB and hands out nonces for the accounts it
+// tracks, reserving each one exactly once until it is either used or
+// explicitly released via RemoveNonce. It is safe for concurrent use.
+type ManagedState struct {
+	*StateDB
+
+	mu sync.Mutex
+
+	accounts map[string]*account
+}
+
+// ManageState returns a new managed state with the statedb as its backing
+// layer.
+func ManageState(statedb *StateDB) *ManagedState {
+	return &ManagedState{StateDB: statedb, accounts: make(map[string]*account)}
+}
+
+// getAccount returns the managed account for addr, creating it if this is the
+// first time it is seen. If the account's on-chain nonce has advanced past
+// what the managed state knows about (e.g. a transaction was mined that this
+// node never reserved a nonce for), the bitmap is reset to start tracking
+// from the new nonce. Callers must hold ms.mu.
+func (ms *ManagedState) getAccount(addr common.Address) *account {
+	if acc, ok := ms.accounts[addr.Str()]; ok {
+		if so := acc.stateObject; so.nonce > acc.nstart {
+			acc.nstart = so.nonce
+			acc.nonces = nil
+		}
+		return acc
+	}
+	so := ms.StateDB.GetOrNewStateObject(addr)
+	acc := newAccount(so)
+	ms.accounts[addr.Str()] = acc
+	return acc
+}
+
+// NewNonce returns the next free nonce for addr, reserving it so that it is
+// never handed out again until released with RemoveNonce.
+func (ms *ManagedState) NewNonce(addr common.Address) uint64 {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	acc := ms.getAccount(addr)
+
+	var i uint64
+	for i = 0; i < uint64(len(acc.nonces)); i++ {
+		if !acc.nonces[i] {
+			break
+		}
+	}
+	if i == uint64(len(acc.nonces)) {
+		acc.nonces = append(acc.nonces, true)
+	} else {
+		acc.nonces[i] = true
+	}
+	return acc.nstart + i
+}
+
+// RemoveNonce releases nonce n for addr and discards any reservations above
+// it, so it (and any nonce above it) can be handed out again by NewNonce.
+func (ms *ManagedState) RemoveNonce(addr common.Address, n uint64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	acc, ok := ms.accounts[addr.Str()]
+	if !ok {
+		return
+	}
+	if n-acc.nstart <= uint64(len(acc.nonces)) {
+		reslice := make([]bool, n-acc.nstart)
+		copy(reslice, acc.nonces[:n-acc.nstart])
+		acc.nonces = reslice
+	}
+}
+
+// GapNonces returns the nonces below the highest reserved nonce for addr that
+// are not currently marked as used, i.e. the holes left by Reconcile releasing
+// nonces that a caller can still fill.
+func (ms *ManagedState) GapNonces(addr common.Address) []uint64 {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	acc, ok := ms.accounts[addr.Str()]
+	if !ok {
+		return nil
+	}
+	highest := len(acc.nonces) - 1
+	for highest >= 0 && !acc.nonces[highest] {
+		highest--
+	}
+	var gaps []uint64
+	for i, used := range acc.nonces[:highest+1] {
+		if !used {
+			gaps = append(gaps, acc.nstart+uint64(i))
+		}
+	}
+	return gaps
+}
+
+// Reconcile reconciles addr's reserved nonces against pendingNonces, the set
+// of nonces the node's transaction pool currently still considers pending.
+// Any nonce reserved by a prior NewNonce call that is no longer present in
+// pendingNonces is released, so a node that restored a stale Snapshot (or
+// whose pending transactions were dropped) doesn't permanently refuse to
+// reuse those nonces.
+func (ms *ManagedState) Reconcile(addr common.Address, pendingNonces []uint64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	acc, ok := ms.accounts[addr.Str()]
+	if !ok {
+		return
+	}
+	pending := make(map[uint64]bool, len(pendingNonces))
+	for _, n := range pendingNonces {
+		pending[n] = true
+	}
+	for i := range acc.nonces {
+		if !pending[acc.nstart+uint64(i)] {
+			acc.nonces[i] = false
+		}
+	}
+}
+
+// managedAccountRLP is the RLP-serializable form of a tracked account, used
+// by Snapshot and Restore.
+type managedAccountRLP struct {
+	Address common.Address
+	Nstart  uint64
+	Nonces  []bool
+}
+
+// Snapshot serializes the managed nonce state (nstart and the nonce bitmap of
+// every tracked account) to w, so a node can restart without losing track of
+// its reserved nonces and re-broadcasting pending transactions.
+func (ms *ManagedState) Snapshot(w io.Writer) error {
+	ms.mu.Lock()
+	accounts := make([]managedAccountRLP, 0, len(ms.accounts))
+	for _, acc := range ms.accounts {
+		nonces := make([]bool, len(acc.nonces))
+		copy(nonces, acc.nonces)
+
+		accounts = append(accounts, managedAccountRLP{
+			Address: acc.stateObject.address,
+			Nstart:  acc.nstart,
+			Nonces:  nonces,
+		})
+	}
+	ms.mu.Unlock()
+
+	return rlp.Encode(w, accounts)
+}
+
+// Restore reads back a snapshot produced by Snapshot, re-establishing the
+// nstart and nonce bitmap of every account it contains that still exists in
+// the backing StateDB. Accounts no longer present in the state are silently
+// dropped, since there is nothing to reconcile them against.
+func (ms *ManagedState) Restore(r io.Reader) error {
+	var accounts []managedAccountRLP
+	if err := rlp.Decode(r, &accounts); err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, saved := range accounts {
+		so := ms.StateDB.GetStateObject(saved.Address)
+		if so == nil {
+			continue
+		}
+		acc := newAccount(so)
+		acc.nstart = saved.Nstart
+		acc.nonces = saved.Nonces
+		ms.accounts[saved.Address.Str()] = acc
+	}
+	return nil
+}
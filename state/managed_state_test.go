@@ -1,6 +1,8 @@
 package state
 
 import (
+	"bytes"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -87,3 +89,71 @@ func TestRemoteNonceChange(t *testing.T) {
 		t.Error("expected nonce after remote update to be", 201, "got", nonce)
 	}
 }
+
+func TestSnapshotRestore(t *testing.T) {
+	ms, account := create()
+
+	ms.NewNonce(addr)
+	ms.NewNonce(addr)
+	ms.RemoveNonce(addr, account.nstart+1)
+
+	buf := new(bytes.Buffer)
+	if err := ms.Snapshot(buf); err != nil {
+		t.Fatal("unexpected error taking snapshot:", err)
+	}
+
+	restored := ManageState(ms.StateDB)
+	if err := restored.Restore(buf); err != nil {
+		t.Fatal("unexpected error restoring snapshot:", err)
+	}
+
+	nonce := restored.NewNonce(addr)
+	if nonce != 101 {
+		t.Error("expected restored nonce to be 101. got", nonce)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ms, account := create()
+
+	for i := 0; i < 5; i++ {
+		ms.NewNonce(addr)
+	}
+	ms.Reconcile(addr, []uint64{account.nstart, account.nstart + 2})
+
+	gaps := ms.GapNonces(addr)
+	if len(gaps) != 1 {
+		t.Error("expected 1 gap nonce after reconcile. got", len(gaps))
+	}
+
+	nonce := ms.NewNonce(addr)
+	if nonce != account.nstart+1 {
+		t.Error("expected reconcile to free up nonce", account.nstart+1, "got", nonce)
+	}
+}
+
+func TestNewNonceConcurrent(t *testing.T) {
+	ms, _ := create()
+
+	var wg sync.WaitGroup
+	seen := make([]bool, 100)
+	var mu sync.Mutex
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce := ms.NewNonce(addr)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if idx := nonce - 100; idx < uint64(len(seen)) {
+				if seen[idx] {
+					t.Error("nonce handed out twice:", nonce)
+				}
+				seen[idx] = true
+			}
+		}()
+	}
+	wg.Wait()
+}